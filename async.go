@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// AsyncBlockOnFull controls the backpressure policy used by AsyncHandler
+// when its queue is full. If true, Write blocks until space is available;
+// if false (the default), the message is dropped and AsyncDropped is
+// incremented.
+var AsyncBlockOnFull = false
+
+// AsyncHandler wraps a RotatingFileHandler and writes to it from a single
+// background goroutine, so callers of Write never block on disk I/O.
+type AsyncHandler struct {
+	h     *RotatingFileHandler
+	queue chan []byte
+	flush chan chan struct{}
+	done  chan struct{}
+	wg    sync.WaitGroup
+	block bool
+
+	dropped int64
+	pool    sync.Pool
+}
+
+// NewAsyncHandler starts a background goroutine that drains messages into h.
+// bufSize is the number of messages the queue can hold before the drop-or-
+// block policy (AsyncBlockOnFull) kicks in.
+func NewAsyncHandler(h *RotatingFileHandler, bufSize int) *AsyncHandler {
+	a := &AsyncHandler{
+		h:     h,
+		queue: make(chan []byte, bufSize),
+		flush: make(chan chan struct{}),
+		done:  make(chan struct{}),
+		block: AsyncBlockOnFull,
+		pool:  sync.Pool{New: func() interface{} { return make([]byte, 0, 256) }},
+	}
+	a.wg.Add(1)
+	go a.run()
+	return a
+}
+
+func (a *AsyncHandler) run() {
+	defer a.wg.Done()
+	for {
+		select {
+		case p := <-a.queue:
+			a.write(p)
+		case ack := <-a.flush:
+			a.drain()
+			close(ack)
+		case <-a.done:
+			a.drain()
+			return
+		}
+	}
+}
+
+func (a *AsyncHandler) write(p []byte) {
+	a.h.Write(p)
+	a.pool.Put(p[:0])
+}
+
+// drain writes out everything currently queued without blocking.
+func (a *AsyncHandler) drain() {
+	for {
+		select {
+		case p := <-a.queue:
+			a.write(p)
+		default:
+			return
+		}
+	}
+}
+
+// Write enqueues p and returns immediately; the background goroutine
+// performs the actual write. p is copied, so the caller's buffer is safe
+// to reuse once Write returns.
+func (a *AsyncHandler) Write(p []byte) (int, error) {
+	buf := a.pool.Get().([]byte)
+	buf = append(buf[:0], p...)
+
+	if a.block {
+		a.queue <- buf
+		return len(p), nil
+	}
+
+	select {
+	case a.queue <- buf:
+	default:
+		atomic.AddInt64(&a.dropped, 1)
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of messages dropped because the queue was
+// full and AsyncBlockOnFull is false.
+func (a *AsyncHandler) Dropped() int64 {
+	return atomic.LoadInt64(&a.dropped)
+}
+
+// Flush blocks until every message queued so far has been written and
+// synced to disk.
+func (a *AsyncHandler) Flush() {
+	ack := make(chan struct{})
+	select {
+	case a.flush <- ack:
+		<-ack
+	case <-a.done:
+		return
+	}
+	a.h.Sync()
+}
+
+// Close flushes any remaining messages, stops the background goroutine and
+// closes the underlying file. It is safe to call only once.
+func (a *AsyncHandler) Close() error {
+	close(a.done)
+	a.wg.Wait()
+	return a.h.Close()
+}
+
+// StartAsync is like StartEx but writes to the log file from a background
+// goroutine, so Debug/Info/Warn/Error/Fatal never block on disk I/O.
+// bufSize sets the number of messages the internal queue can hold.
+func StartAsync(level int32, path string, maxBytes, backupCount, bufSize int) {
+	doLoggingAsync(level, path, maxBytes, backupCount, bufSize)
+}
+
+func doLoggingAsync(logLevel int32, fileName string, maxBytes, backupCount, bufSize int) {
+	if fileName == "" {
+		defaultLogger = buildLogger(logLevel, nil, nil)
+		return
+	}
+
+	fileHandler, err := NewRotatingFileHandler(fileName, maxBytes, backupCount)
+	if err != nil {
+		log.Fatal("unable to create RotatingFileHandler: ", err)
+	}
+
+	async := NewAsyncHandler(fileHandler, bufSize)
+	defaultLogger = buildLogger(logLevel, async, nil)
+	defaultLogger.async = async
+}
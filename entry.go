@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+)
+
+// Entry is a log record carrying contextual fields, created via WithFields
+// for structured logging. Its Debug/Info/Warn/Error/Fatal methods mirror
+// the package-level functions but attach fields to every line.
+type Entry struct {
+	fields map[string]interface{}
+}
+
+// WithFields returns an Entry that attaches fields to every subsequent
+// Debug/Info/Warn/Error/Fatal call made through it.
+func WithFields(fields map[string]interface{}) *Entry {
+	return &Entry{fields: fields}
+}
+
+func (e *Entry) Debug(format string, a ...interface{}) {
+	if !allowSampling(LevelDebug, format) {
+		return
+	}
+	logLine(defaultLogger.Debug, LevelDebug, "DEBUG", fmt.Sprintf(format, a...), e.fields)
+}
+
+func (e *Entry) Info(format string, a ...interface{}) {
+	if !allowSampling(LevelInfo, format) {
+		return
+	}
+	logLine(defaultLogger.Info, LevelInfo, "INFO", fmt.Sprintf(format, a...), e.fields)
+}
+
+func (e *Entry) Warn(format string, a ...interface{}) {
+	if !allowSampling(LevelWarn, format) {
+		return
+	}
+	logLine(defaultLogger.Warn, LevelWarn, "WARNING", fmt.Sprintf(format, a...), e.fields)
+}
+
+func (e *Entry) Error(err error) {
+	msg := err.Error()
+	if !allowSampling(LevelError, msg) {
+		return
+	}
+	logLine(defaultLogger.Error, LevelError, "ERROR", msg, e.fields)
+}
+
+func (e *Entry) Errorf(format string, a ...interface{}) {
+	if !allowSampling(LevelError, format) {
+		return
+	}
+	logLine(defaultLogger.Error, LevelError, "ERROR", fmt.Sprintf(format, a...), e.fields)
+}
+
+// Fatal writes to the Fatal destination with e's fields and exits with an
+// error 255 code.
+func (e *Entry) Fatal(a ...interface{}) {
+	logLine(defaultLogger.Fatal, LevelError, "FATAL", fmt.Sprint(a...), e.fields)
+	Sync()
+	os.Exit(255)
+}
+
+// Fatalf writes to the Fatal destination with e's fields and exits with an
+// error 255 code.
+func (e *Entry) Fatalf(format string, a ...interface{}) {
+	logLine(defaultLogger.Fatal, LevelError, "FATAL", fmt.Sprintf(format, a...), e.fields)
+	Sync()
+	os.Exit(255)
+}
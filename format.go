@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Formatter renders one log record into a single line of output, including
+// any trailing newline.
+type Formatter interface {
+	Format(tag string, t time.Time, caller, msg string, fields map[string]interface{}) ([]byte, error)
+}
+
+// TextFormatter reproduces the plain "TAG: date time file:line: msg" shape
+// this package has always used, plus any fields appended as "key=value".
+type TextFormatter struct{}
+
+func (TextFormatter) Format(tag string, t time.Time, caller, msg string, fields map[string]interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(tag)
+	b.WriteString(": ")
+	b.WriteString(t.Format("2006/01/02 15:04:05"))
+	b.WriteString(" ")
+	b.WriteString(caller)
+	b.WriteString(": ")
+	b.WriteString(msg)
+
+	for _, k := range sortedKeys(fields) {
+		fmt.Fprintf(&b, " %s=%v", k, fields[k])
+	}
+
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// JSONFormatter renders each record as a single-line JSON object with
+// "time", "level", "msg", "caller" and any user-supplied fields.
+type JSONFormatter struct{}
+
+func (JSONFormatter) Format(tag string, t time.Time, caller, msg string, fields map[string]interface{}) ([]byte, error) {
+	out := make(map[string]interface{}, len(fields)+4)
+	for k, v := range fields {
+		out[k] = v
+	}
+	out["time"] = t.Format(time.RFC3339)
+	out["level"] = tag
+	out["msg"] = msg
+	out["caller"] = caller
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func sortedKeys(fields map[string]interface{}) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Hook receives a copy of every log message whose level matches one of the
+// levels returned by Levels. Fire is called asynchronously and must not
+// block for long, since a slow hook only slows itself down, never the
+// caller of Debug/Info/Warn/Error/Fatal.
+type Hook interface {
+	Levels() []int32
+	Fire(level int32, msg []byte, fields map[string]interface{}) error
+}
+
+// hookQueueSize bounds how many pending Fire calls a single hook may have
+// in flight before further dispatches to it are dropped.
+const hookQueueSize = 256
+
+type hookEntry struct {
+	hook    Hook
+	queue   chan hookMsg
+	dropped int64
+}
+
+type hookMsg struct {
+	level  int32
+	msg    []byte
+	fields map[string]interface{}
+}
+
+type hookRegistry struct {
+	mu    sync.RWMutex
+	hooks []*hookEntry
+}
+
+func newHookEntry(h Hook) *hookEntry {
+	e := &hookEntry{hook: h, queue: make(chan hookMsg, hookQueueSize)}
+	go e.run()
+	return e
+}
+
+func (e *hookEntry) run() {
+	for m := range e.queue {
+		e.hook.Fire(m.level, m.msg, m.fields)
+	}
+}
+
+func (e *hookEntry) dispatch(level int32, msg []byte, fields map[string]interface{}) {
+	for _, lvl := range e.hook.Levels() {
+		if lvl != level {
+			continue
+		}
+		buf := make([]byte, len(msg))
+		copy(buf, msg)
+		select {
+		case e.queue <- hookMsg{level: level, msg: buf, fields: fields}:
+		default:
+			atomic.AddInt64(&e.dropped, 1)
+		}
+		return
+	}
+}
+
+// AddHook registers a hook on the default logger. The hook runs in its own
+// goroutine so a slow or blocking Fire cannot stall Debug/Info/Warn/Error.
+func AddHook(h Hook) {
+	defaultLogger.hooks.mu.Lock()
+	defer defaultLogger.hooks.mu.Unlock()
+	defaultLogger.hooks.hooks = append(defaultLogger.hooks.hooks, newHookEntry(h))
+}
+
+// RemoveHook unregisters every previously added hook equal to h.
+func RemoveHook(h Hook) {
+	defaultLogger.hooks.mu.Lock()
+	defer defaultLogger.hooks.mu.Unlock()
+	kept := defaultLogger.hooks.hooks[:0]
+	for _, e := range defaultLogger.hooks.hooks {
+		if e.hook == h {
+			close(e.queue)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	defaultLogger.hooks.hooks = kept
+}
+
+// fireHooks dispatches msg to every registered hook whose Levels() include
+// level. It never blocks the caller on a slow hook.
+func fireHooks(level int32, msg []byte, fields map[string]interface{}) {
+	defaultLogger.hooks.mu.RLock()
+	defer defaultLogger.hooks.mu.RUnlock()
+	for _, e := range defaultLogger.hooks.hooks {
+		e.dispatch(level, msg, fields)
+	}
+}
@@ -0,0 +1,74 @@
+// Package http implements a logger.Hook that POSTs log messages as JSON to
+// a webhook URL, retrying transient failures.
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Hook POSTs a JSON payload to URL for every fired message. It satisfies
+// the logger.Hook interface structurally, without importing the logger
+// package.
+type Hook struct {
+	URL        string
+	Client     *http.Client
+	Retries    int
+	RetryDelay time.Duration
+
+	levels []int32
+}
+
+// New returns a Hook that POSTs to url for the given levels, retrying up to
+// retries times on failure with a fixed delay between attempts.
+func New(url string, levels []int32, retries int, retryDelay time.Duration) *Hook {
+	return &Hook{
+		URL:        url,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		Retries:    retries,
+		RetryDelay: retryDelay,
+		levels:     levels,
+	}
+}
+
+// Levels returns the log levels this hook was constructed with.
+func (h *Hook) Levels() []int32 {
+	return h.levels
+}
+
+type payload struct {
+	Level  int32                  `json:"level"`
+	Msg    string                 `json:"msg"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Fire POSTs msg and fields to h.URL, retrying on failure or a non-2xx
+// response.
+func (h *Hook) Fire(level int32, msg []byte, fields map[string]interface{}) error {
+	body, err := json.Marshal(payload{Level: level, Msg: string(msg), Fields: fields})
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= h.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(h.RetryDelay)
+		}
+
+		resp, err := h.Client.Post(h.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	}
+	return lastErr
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHookFirePostsPayload(t *testing.T) {
+	var mu sync.Mutex
+	var got payload
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		json.NewDecoder(r.Body).Decode(&got)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	h := New(srv.URL, []int32{4}, 0, 0)
+	if err := h.Fire(4, []byte("boom"), map[string]interface{}{"k": "v"}); err != nil {
+		t.Fatalf("Fire: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got.Level != 4 || got.Msg != "boom" || got.Fields["k"] != "v" {
+		t.Fatalf("got %+v, want level=4 msg=boom fields[k]=v", got)
+	}
+}
+
+func TestHookFireRetriesOnFailureThenSucceeds(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		if n < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	h := New(srv.URL, []int32{4}, 2, time.Millisecond)
+	if err := h.Fire(4, []byte("boom"), nil); err != nil {
+		t.Fatalf("Fire: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 3 {
+		t.Fatalf("got %d attempts, want 3", attempts)
+	}
+}
+
+func TestHookFireReturnsErrorAfterExhaustingRetries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	h := New(srv.URL, []int32{4}, 1, time.Millisecond)
+	if err := h.Fire(4, []byte("boom"), nil); err == nil {
+		t.Fatal("expected an error once retries are exhausted")
+	}
+}
@@ -0,0 +1,104 @@
+// Package smtp implements a logger.Hook that batches Error/Fatal messages
+// and emails them on a timer instead of one message per log line.
+package smtp
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mirrors logger.LevelError so Fire only batches error-and-above messages
+// by default; callers may pass different levels to New.
+const levelError int32 = 1 << 3
+
+// Hook batches fired messages and flushes them over SMTP every interval
+// (or when Close is called). It satisfies the logger.Hook interface
+// structurally, without importing the logger package.
+type Hook struct {
+	Addr     string
+	Auth     smtp.Auth
+	From     string
+	To       []string
+	Subject  string
+	Interval time.Duration
+
+	levels []int32
+
+	mu      sync.Mutex
+	pending []string
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// New returns a Hook that batches messages at the given levels (defaults to
+// LevelError-equivalent if levels is nil) and emails them every interval.
+func New(addr string, auth smtp.Auth, from string, to []string, subject string, interval time.Duration, levels []int32) *Hook {
+	if levels == nil {
+		levels = []int32{levelError}
+	}
+	h := &Hook{
+		Addr:     addr,
+		Auth:     auth,
+		From:     from,
+		To:       to,
+		Subject:  subject,
+		Interval: interval,
+		levels:   levels,
+		done:     make(chan struct{}),
+	}
+	h.wg.Add(1)
+	go h.run()
+	return h
+}
+
+// Levels returns the log levels this hook was constructed with.
+func (h *Hook) Levels() []int32 {
+	return h.levels
+}
+
+// Fire appends msg to the pending batch.
+func (h *Hook) Fire(level int32, msg []byte, fields map[string]interface{}) error {
+	h.mu.Lock()
+	h.pending = append(h.pending, string(msg))
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *Hook) run() {
+	defer h.wg.Done()
+	ticker := time.NewTicker(h.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.flush()
+		case <-h.done:
+			h.flush()
+			return
+		}
+	}
+}
+
+func (h *Hook) flush() error {
+	h.mu.Lock()
+	batch := h.pending
+	h.pending = nil
+	h.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	body := fmt.Sprintf("Subject: %s\r\n\r\n%s\r\n", h.Subject, strings.Join(batch, "\r\n"))
+	return smtp.SendMail(h.Addr, h.Auth, h.From, h.To, []byte(body))
+}
+
+// Close flushes any pending messages and stops the batching goroutine.
+func (h *Hook) Close() error {
+	close(h.done)
+	h.wg.Wait()
+	return nil
+}
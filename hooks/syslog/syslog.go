@@ -0,0 +1,54 @@
+// Package syslog implements a logger.Hook that forwards log messages to the
+// local or a remote syslog daemon.
+package syslog
+
+import (
+	"log/syslog"
+)
+
+// Hook forwards fired messages to a syslog.Writer. It satisfies the
+// logger.Hook interface structurally, without importing the logger package.
+type Hook struct {
+	writer *syslog.Writer
+	levels []int32
+}
+
+// Mirrors logger.LevelDebug/Info/Warn/Error so Fire can pick a syslog
+// severity without importing the logger package.
+const (
+	levelDebug int32 = 1 << iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+// New dials network/raddr using syslog.Dial (raddr == "" connects to the
+// local syslog daemon) and returns a Hook that fires for the given levels.
+// priority/tag are passed through to syslog.Dial unchanged.
+func New(network, raddr string, priority syslog.Priority, tag string, levels []int32) (*Hook, error) {
+	w, err := syslog.Dial(network, raddr, priority, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &Hook{writer: w, levels: levels}, nil
+}
+
+// Levels returns the log levels this hook was constructed with.
+func (h *Hook) Levels() []int32 {
+	return h.levels
+}
+
+// Fire writes msg to syslog at a severity derived from level.
+func (h *Hook) Fire(level int32, msg []byte, fields map[string]interface{}) error {
+	line := string(msg)
+	switch level {
+	case levelDebug:
+		return h.writer.Debug(line)
+	case levelInfo:
+		return h.writer.Info(line)
+	case levelWarn:
+		return h.writer.Warning(line)
+	default: // levelError and Fatal
+		return h.writer.Err(line)
+	}
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type testHook struct {
+	levels []int32
+	mu     sync.Mutex
+	fired  []string
+	delay  time.Duration
+}
+
+func (h *testHook) Levels() []int32 { return h.levels }
+
+func (h *testHook) Fire(level int32, msg []byte, fields map[string]interface{}) error {
+	if h.delay > 0 {
+		time.Sleep(h.delay)
+	}
+	h.mu.Lock()
+	h.fired = append(h.fired, string(msg))
+	h.mu.Unlock()
+	return nil
+}
+
+func TestHookFiresForMatchingLevel(t *testing.T) {
+	StartWithConfig(Config{Level: LevelDebug})
+
+	h := &testHook{levels: []int32{LevelInfo}}
+	AddHook(h)
+	defer RemoveHook(h)
+
+	Info("hello")
+	Debug("should not fire")
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		h.mu.Lock()
+		n := len(h.fired)
+		h.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.fired) != 1 {
+		t.Fatalf("got %d fired messages, want 1: %v", len(h.fired), h.fired)
+	}
+}
+
+func TestAddHookBeforeStartSurvivesStart(t *testing.T) {
+	h := &testHook{levels: []int32{LevelInfo}}
+	AddHook(h)
+	defer RemoveHook(h)
+
+	StartWithConfig(Config{Level: LevelDebug})
+
+	Info("hello")
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		h.mu.Lock()
+		n := len(h.fired)
+		h.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.fired) != 1 {
+		t.Fatalf("got %d fired messages, want 1: %v", len(h.fired), h.fired)
+	}
+}
+
+func TestHookDispatchConcurrentDropIsRaceFree(t *testing.T) {
+	StartWithConfig(Config{Level: LevelDebug})
+
+	h := &testHook{levels: []int32{LevelInfo}, delay: 5 * time.Millisecond}
+	AddHook(h)
+	defer RemoveHook(h)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2*hookQueueSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Info("flood")
+		}()
+	}
+	wg.Wait()
+}
@@ -7,7 +7,10 @@ import (
 	"log"
 	"os"
 	"path"
+	"runtime"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 const (
@@ -30,22 +33,45 @@ type Logger struct {
 	Fatal *log.Logger
 
 	LogFile *RotatingFileHandler
+
+	async     *AsyncHandler
+	hooks     *hookRegistry
+	timedFile *TimedRotatingFileHandler
+	formatter Formatter
 }
 
-var defaultLogger Logger
+// defaultLogger.hooks is initialized up front so AddHook/RemoveHook are
+// safe to call before Start/StartEx/StartAsync/StartWithConfig.
+var defaultLogger = Logger{hooks: new(hookRegistry)}
 
-// DefaultFlags used by created loggers
+// DefaultFlags is kept for source compatibility but no longer affects
+// output: formatting is now the responsibility of the active Formatter
+// (TextFormatter reproduces the previous "date time file:line: msg" shape).
 var DefaultFlags = log.Ldate | log.Ltime | log.Lshortfile
 
 // RotatingFileHandler writes log a file, if file size exceeds maxBytes,
 // it will backup current file and open a new one.
 //
 // max backup file number is set by backupCount, it will delete oldest if backups too many.
+//
+// All state is protected by mu, so Write is safe to call concurrently and
+// a rollover in progress can't race with one just starting.
 type RotatingFileHandler struct {
+	mu          sync.Mutex
 	fd          *os.File
 	fileName    string
 	maxBytes    int
 	backupCount int
+
+	// compress gzips each rotated backup in the background, as ".gz".
+	compress bool
+	// maxAge, if non-zero, additionally deletes backups older than it,
+	// regardless of backupCount.
+	maxAge time.Duration
+
+	// rotated is closed and replaced every time doRollover rotates the
+	// file, so a Tail goroutine blocked on it wakes up and reopens.
+	rotated chan struct{}
 }
 
 // NewRotatingFileHandler creates dirs and opens the logfile
@@ -64,6 +90,7 @@ func NewRotatingFileHandler(fileName string, maxBytes int, backupCount int) (*Ro
 	h.fileName = fileName
 	h.maxBytes = maxBytes
 	h.backupCount = backupCount
+	h.rotated = make(chan struct{})
 
 	h.fd, err = os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
@@ -73,42 +100,114 @@ func NewRotatingFileHandler(fileName string, maxBytes int, backupCount int) (*Ro
 }
 
 func (h *RotatingFileHandler) Write(p []byte) (n int, err error) {
-	h.doRollover()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.doRollover(); err != nil {
+		return 0, err
+	}
 	return h.fd.Write(p)
 }
 
 func (h *RotatingFileHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	if h.fd != nil {
 		return h.fd.Close()
 	}
 	return nil
 }
 
-func (h *RotatingFileHandler) doRollover() {
+// doRollover checks the current file size and, if it has reached maxBytes,
+// shifts numbered backups up by one, renames the current file to ".1" and
+// reopens fileName. Callers must hold h.mu.
+func (h *RotatingFileHandler) doRollover() error {
 	f, err := h.fd.Stat()
 	if err != nil {
-		return
+		return err
 	}
 
-	if h.maxBytes <= 0 {
-		return
-	} else if f.Size() < int64(h.maxBytes) {
-		return
+	if h.maxBytes <= 0 || f.Size() < int64(h.maxBytes) {
+		return nil
+	}
+
+	if h.backupCount <= 0 {
+		return nil
+	}
+
+	if err := h.fd.Close(); err != nil {
+		return err
+	}
+
+	shiftErr := h.shiftBackups()
+
+	// Reopen fileName regardless of shiftErr: a failed rename must not
+	// leave h.fd pointing at the fd we just closed, or every later Write
+	// fails forever instead of just this rollover.
+	fd, err := os.OpenFile(h.fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		if shiftErr != nil {
+			return shiftErr
+		}
+		return err
+	}
+	h.fd = fd
+
+	if shiftErr != nil {
+		return shiftErr
 	}
 
-	if h.backupCount > 0 {
-		h.fd.Close()
+	dfn := fmt.Sprintf("%s.1", h.fileName)
+	if h.compress {
+		compressFile(dfn)
+	}
+	if h.maxAge > 0 {
+		pruneBackupsByAge(h.fileName, h.maxAge)
+	}
+
+	close(h.rotated)
+	h.rotated = make(chan struct{})
+	return nil
+}
 
-		for i := h.backupCount - 1; i > 0; i-- {
-			sfn := fmt.Sprintf("%s.%d", h.fileName, i)
-			dfn := fmt.Sprintf("%s.%d", h.fileName, i+1)
-			os.Rename(sfn, dfn)
+// shiftBackups renames fileName.(backupCount-1) up through fileName.1 (and
+// their compressed ".gz" forms) by one slot, then renames fileName itself
+// to fileName.1. Callers must hold h.mu and have already closed h.fd.
+func (h *RotatingFileHandler) shiftBackups() error {
+	for i := h.backupCount - 1; i > 0; i-- {
+		sfn := fmt.Sprintf("%s.%d", h.fileName, i)
+		dfn := fmt.Sprintf("%s.%d", h.fileName, i+1)
+		if err := os.Rename(sfn, dfn); err != nil && !os.IsNotExist(err) {
+			return err
 		}
+		if err := os.Rename(sfn+".gz", dfn+".gz"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	dfn := fmt.Sprintf("%s.1", h.fileName)
+	if err := os.Rename(h.fileName, dfn); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// rotateSignal returns the channel that is closed the next time the file
+// rotates.
+func (h *RotatingFileHandler) rotateSignal() <-chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.rotated
+}
 
-		dfn := fmt.Sprintf("%s.1", h.fileName)
-		os.Rename(h.fileName, dfn)
-		h.fd, _ = os.OpenFile(h.fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+// Sync commits the current file's in-memory data to stable storage.
+func (h *RotatingFileHandler) Sync() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.fd != nil {
+		return h.fd.Sync()
 	}
+	return nil
 }
 
 func Start(level int32, path string) {
@@ -120,6 +219,12 @@ func StartEx(level int32, path string, maxBytes, backupCount int) {
 }
 
 func Stop() error {
+	if defaultLogger.async != nil {
+		return defaultLogger.async.Close()
+	}
+	if defaultLogger.timedFile != nil {
+		return defaultLogger.timedFile.Close()
+	}
 	if defaultLogger.LogFile != nil {
 		return defaultLogger.LogFile.Close()
 	}
@@ -130,20 +235,47 @@ func Stop() error {
 //Typically, this means flushing the file system's in-memory copy
 //of recently written data to disk.
 func Sync() {
+	if defaultLogger.async != nil {
+		defaultLogger.async.Flush()
+		return
+	}
+	if defaultLogger.timedFile != nil {
+		defaultLogger.timedFile.Sync()
+		return
+	}
 	if defaultLogger.LogFile != nil {
-		defaultLogger.LogFile.fd.Sync()
+		defaultLogger.LogFile.Sync()
 	}
 }
 
 func doLogging(logLevel int32, fileName string, maxBytes, backupCount int) {
+	var fileHandler *RotatingFileHandler
+	var fileWriter io.Writer
+	if fileName != "" {
+		var err error
+		fileHandler, err = NewRotatingFileHandler(fileName, maxBytes, backupCount)
+		if err != nil {
+			log.Fatal("unable to create RotatingFileHandler: ", err)
+		}
+		fileWriter = fileHandler
+	}
+
+	defaultLogger = buildLogger(logLevel, fileWriter, nil)
+	defaultLogger.LogFile = fileHandler
+}
+
+// buildLogger wires up the per-level *log.Logger destinations for logLevel,
+// tee'ing to fileWriter in addition to stdout/stderr whenever fileWriter is
+// non-nil, and formatting every line with formatter (TextFormatter{} if
+// nil). It does not set Logger.LogFile or Logger.async; callers fill in
+// whichever applies.
+func buildLogger(logLevel int32, fileWriter io.Writer, formatter Formatter) Logger {
 	debugHandle := ioutil.Discard
 	infoHandle := ioutil.Discard
 	warnHandle := ioutil.Discard
 	errorHandle := ioutil.Discard
 	fatalHandle := ioutil.Discard
 
-	var fileHandler *RotatingFileHandler
-
 	switch logLevel {
 	case LevelDebug:
 		debugHandle = os.Stdout
@@ -159,76 +291,119 @@ func doLogging(logLevel int32, fileName string, maxBytes, backupCount int) {
 		fatalHandle = os.Stderr
 	}
 
-	if fileName != "" {
-		var err error
-		fileHandler, err = NewRotatingFileHandler(fileName, maxBytes, backupCount)
-		if err != nil {
-			log.Fatal("unable to create RotatingFileHandler: ", err)
-		}
-
+	if fileWriter != nil {
 		if debugHandle == os.Stdout {
-			debugHandle = io.MultiWriter(fileHandler, debugHandle)
+			debugHandle = io.MultiWriter(fileWriter, debugHandle)
 		}
 
 		if infoHandle == os.Stdout {
-			infoHandle = io.MultiWriter(fileHandler, infoHandle)
+			infoHandle = io.MultiWriter(fileWriter, infoHandle)
 		}
 
 		if warnHandle == os.Stdout {
-			warnHandle = io.MultiWriter(fileHandler, warnHandle)
+			warnHandle = io.MultiWriter(fileWriter, warnHandle)
 		}
 
 		if errorHandle == os.Stderr {
-			errorHandle = io.MultiWriter(fileHandler, errorHandle)
+			errorHandle = io.MultiWriter(fileWriter, errorHandle)
 		}
 
 		if fatalHandle == os.Stderr {
-			fatalHandle = io.MultiWriter(fileHandler, fatalHandle)
+			fatalHandle = io.MultiWriter(fileWriter, fatalHandle)
 		}
 	}
 
-	defaultLogger = Logger{
-		Debug:   log.New(debugHandle, "DEBUG: ", DefaultFlags),
-		Info:    log.New(infoHandle, "INFO: ", DefaultFlags),
-		Warn:    log.New(warnHandle, "WARNING: ", DefaultFlags),
-		Error:   log.New(errorHandle, "ERROR: ", DefaultFlags),
-		Fatal:   log.New(errorHandle, "FATAL: ", DefaultFlags),
-		LogFile: fileHandler,
+	if formatter == nil {
+		formatter = TextFormatter{}
+	}
+
+	l := Logger{
+		// Prefix and flags are left empty/zero: formatter now owns the
+		// tag, timestamp and caller that log.Logger used to add itself.
+		Debug: log.New(debugHandle, "", 0),
+		Info:  log.New(infoHandle, "", 0),
+		Warn:  log.New(warnHandle, "", 0),
+		Error: log.New(errorHandle, "", 0),
+		Fatal: log.New(errorHandle, "", 0),
+		// Carry over any hooks already registered via AddHook before
+		// Start/StartEx/StartAsync/StartWithConfig ran, instead of
+		// silently dropping them.
+		hooks:     defaultLogger.hooks,
+		formatter: formatter,
+	}
+	atomic.StoreInt32(&l.Level, int32(logLevel))
+	return l
+}
+
+// logLine formats msg (plus any fields) through the active formatter and
+// writes it to dest, then dispatches it to hooks registered for
+// levelForHooks. The caller is looked up at a fixed runtime.Caller depth of
+// 3, i.e. whoever called the Debug/Info/... function (or Entry method) that
+// called logLine.
+func logLine(dest *log.Logger, levelForHooks int32, tag, msg string, fields map[string]interface{}) {
+	caller := callerInfo(3)
+	b, err := defaultLogger.formatter.Format(tag, time.Now(), caller, msg, fields)
+	if err != nil {
+		b = []byte(msg + "\n")
 	}
+	dest.Output(0, string(b))
+	fireHooks(levelForHooks, b, fields)
+}
 
-	atomic.StoreInt32(&defaultLogger.Level, int32(logLevel))
+func callerInfo(skip int) string {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "???:0"
+	}
+	return fmt.Sprintf("%s:%d", path.Base(file), line)
 }
 
 func Debug(format string, a ...interface{}) {
-	defaultLogger.Debug.Output(2, fmt.Sprintf(format, a...))
+	if !allowSampling(LevelDebug, format) {
+		return
+	}
+	logLine(defaultLogger.Debug, LevelDebug, "DEBUG", fmt.Sprintf(format, a...), nil)
 }
 
 func Info(format string, a ...interface{}) {
-	defaultLogger.Info.Output(2, fmt.Sprintf(format, a...))
+	if !allowSampling(LevelInfo, format) {
+		return
+	}
+	logLine(defaultLogger.Info, LevelInfo, "INFO", fmt.Sprintf(format, a...), nil)
 }
 
 func Warn(format string, a ...interface{}) {
-	defaultLogger.Warn.Output(2, fmt.Sprintf(format, a...))
+	if !allowSampling(LevelWarn, format) {
+		return
+	}
+	logLine(defaultLogger.Warn, LevelWarn, "WARNING", fmt.Sprintf(format, a...), nil)
 }
 
 func Error(err error) {
-	defaultLogger.Error.Output(2, fmt.Sprintf("%s\n", err))
+	msg := err.Error()
+	if !allowSampling(LevelError, msg) {
+		return
+	}
+	logLine(defaultLogger.Error, LevelError, "ERROR", msg, nil)
 }
 
 func Errorf(format string, a ...interface{}) {
-	defaultLogger.Error.Output(2, fmt.Sprintf(format, a))
+	if !allowSampling(LevelError, format) {
+		return
+	}
+	logLine(defaultLogger.Error, LevelError, "ERROR", fmt.Sprintf(format, a...), nil)
 }
 
 // Fatal writes to the Fatal destination and exits with an error 255 code
 func Fatal(a ...interface{}) {
-	defaultLogger.Fatal.Output(2, fmt.Sprint(a...))
+	logLine(defaultLogger.Fatal, LevelError, "FATAL", fmt.Sprint(a...), nil)
 	Sync()
 	os.Exit(255)
 }
 
 // Fatalf writes to the Fatal destination and exits with an error 255 code
 func Fatalf(format string, a ...interface{}) {
-	defaultLogger.Fatal.Output(2, fmt.Sprintf(format, a...))
+	logLine(defaultLogger.Fatal, LevelError, "FATAL", fmt.Sprintf(format, a...), nil)
 	Sync()
 	os.Exit(255)
 }
@@ -0,0 +1,312 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// When selects the unit used by TimedRotatingFileHandler's rotation
+// schedule.
+type When int
+
+const (
+	WhenSecond When = iota
+	WhenMinute
+	WhenHour
+	WhenDay
+)
+
+func (w When) duration(interval int) time.Duration {
+	switch w {
+	case WhenSecond:
+		return time.Duration(interval) * time.Second
+	case WhenMinute:
+		return time.Duration(interval) * time.Minute
+	case WhenHour:
+		return time.Duration(interval) * time.Hour
+	default:
+		return time.Duration(interval) * 24 * time.Hour
+	}
+}
+
+func (w When) suffixLayout() string {
+	switch w {
+	case WhenSecond:
+		return "2006-01-02_15-04-05"
+	case WhenMinute:
+		return "2006-01-02_15-04"
+	case WhenHour:
+		return "2006-01-02_15"
+	default:
+		return "2006-01-02"
+	}
+}
+
+// TimedRotatingFileHandler writes to a file and rotates it on a schedule
+// (every N seconds/minutes/hours/days) rather than by size, using
+// timestamp-suffixed backups (e.g. "app.log.2024-01-15").
+type TimedRotatingFileHandler struct {
+	mu           sync.Mutex
+	fd           *os.File
+	fileName     string
+	interval     time.Duration
+	suffixLayout string
+	backupCount  int
+
+	// compress gzips each rotated backup in the background, as ".gz".
+	compress bool
+	// maxAge, if non-zero, additionally deletes backups older than it,
+	// regardless of backupCount.
+	maxAge time.Duration
+
+	rolloverAt time.Time
+}
+
+// NewTimedRotatingFileHandler creates dirs and opens fileName, rotating it
+// every interval units of when. backupCount bounds how many rotated files
+// are kept; 0 means unbounded.
+func NewTimedRotatingFileHandler(fileName string, when When, interval, backupCount int) (*TimedRotatingFileHandler, error) {
+	dir := path.Dir(fileName)
+	if err := os.Mkdir(dir, 0775); err != nil && !os.IsExist(err) {
+		return nil, err
+	}
+
+	fd, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &TimedRotatingFileHandler{
+		fd:           fd,
+		fileName:     fileName,
+		interval:     when.duration(interval),
+		suffixLayout: when.suffixLayout(),
+		backupCount:  backupCount,
+	}
+	h.rolloverAt = time.Now().Add(h.interval)
+	return h, nil
+}
+
+// Write rotates fileName if the rotation schedule has elapsed, then writes
+// p to the current file.
+func (h *TimedRotatingFileHandler) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if time.Now().After(h.rolloverAt) {
+		if err := h.doRollover(); err != nil {
+			return 0, err
+		}
+	}
+	return h.fd.Write(p)
+}
+
+// Close closes the underlying file.
+func (h *TimedRotatingFileHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.fd != nil {
+		return h.fd.Close()
+	}
+	return nil
+}
+
+// Sync commits the current file's in-memory data to stable storage.
+func (h *TimedRotatingFileHandler) Sync() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.fd != nil {
+		return h.fd.Sync()
+	}
+	return nil
+}
+
+func (h *TimedRotatingFileHandler) doRollover() error {
+	h.fd.Close()
+
+	dfn := h.fileName + "." + time.Now().Format(h.suffixLayout)
+	renameErr := os.Rename(h.fileName, dfn)
+	if os.IsNotExist(renameErr) {
+		renameErr = nil
+	}
+
+	// Reopen fileName regardless of renameErr: a failed rename must not
+	// leave h.fd pointing at the fd we just closed, or every later Write
+	// fails forever instead of just this rollover.
+	fd, err := os.OpenFile(h.fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		if renameErr != nil {
+			return renameErr
+		}
+		return err
+	}
+	h.fd = fd
+	h.rolloverAt = time.Now().Add(h.interval)
+
+	if renameErr != nil {
+		return renameErr
+	}
+
+	if h.compress {
+		compressFile(dfn)
+	}
+	if h.backupCount > 0 || h.maxAge > 0 {
+		h.prune()
+	}
+	return nil
+}
+
+// prune deletes backups beyond backupCount (oldest first) and any backup
+// older than maxAge.
+func (h *TimedRotatingFileHandler) prune() {
+	dir := path.Dir(h.fileName)
+	base := path.Base(h.fileName)
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var backups []os.FileInfo
+	for _, e := range entries {
+		if e.Name() != base && strings.HasPrefix(e.Name(), base+".") {
+			backups = append(backups, e)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime().Before(backups[j].ModTime())
+	})
+
+	cutoff := time.Now().Add(-h.maxAge)
+	for i, e := range backups {
+		expiredByAge := h.maxAge > 0 && e.ModTime().Before(cutoff)
+		expiredByCount := h.backupCount > 0 && i < len(backups)-h.backupCount
+		if expiredByAge || expiredByCount {
+			os.Remove(path.Join(dir, e.Name()))
+		}
+	}
+}
+
+// compressFile gzips path to path+".gz" in the background and removes the
+// original once done.
+func compressFile(path string) {
+	go func() {
+		if err := gzipAndRemove(path); err != nil {
+			log.Printf("logger: compress %s: %v", path, err)
+		}
+	}()
+}
+
+func gzipAndRemove(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		gw.Close()
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// pruneBackupsByAge deletes every file in fileName's directory whose name
+// is fileName's base name followed by "." and anything (numbered backups,
+// their ".gz" forms, or any other such suffix) and whose mtime is older
+// than maxAge.
+func pruneBackupsByAge(fileName string, maxAge time.Duration) {
+	dir := path.Dir(fileName)
+	base := path.Base(fileName)
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for _, e := range entries {
+		name := e.Name()
+		if name == base || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		if e.ModTime().Before(cutoff) {
+			os.Remove(path.Join(dir, name))
+		}
+	}
+}
+
+// Config configures logging with options beyond what Start/StartEx expose:
+// compression and age-based cleanup of rotated backups, and time-based
+// rotation. It is additive — Start and StartEx are unaffected.
+type Config struct {
+	Level       int32
+	Path        string
+	MaxBytes    int
+	BackupCount int
+	Compress    bool
+	MaxAge      time.Duration
+
+	// When and Interval switch to time-based rotation via
+	// TimedRotatingFileHandler instead of size-based RotatingFileHandler.
+	// Interval is in units of When (e.g. When=WhenHour, Interval=2 rotates
+	// every two hours). Leave Interval at 0 to keep size-based rotation.
+	When     When
+	Interval int
+
+	// Formatter selects how each line is rendered; TextFormatter{} is used
+	// if left nil.
+	Formatter Formatter
+}
+
+// StartWithConfig is like StartEx but accepts a Config for compression,
+// age-based backup cleanup, optional time-based rotation, and a custom
+// Formatter.
+func StartWithConfig(cfg Config) error {
+	if cfg.Path == "" {
+		defaultLogger = buildLogger(cfg.Level, nil, cfg.Formatter)
+		return nil
+	}
+
+	if cfg.Interval > 0 {
+		th, err := NewTimedRotatingFileHandler(cfg.Path, cfg.When, cfg.Interval, cfg.BackupCount)
+		if err != nil {
+			return err
+		}
+		th.compress = cfg.Compress
+		th.maxAge = cfg.MaxAge
+
+		defaultLogger = buildLogger(cfg.Level, th, cfg.Formatter)
+		defaultLogger.timedFile = th
+		return nil
+	}
+
+	fh, err := NewRotatingFileHandler(cfg.Path, cfg.MaxBytes, cfg.BackupCount)
+	if err != nil {
+		return err
+	}
+	fh.compress = cfg.Compress
+	fh.maxAge = cfg.MaxAge
+
+	defaultLogger = buildLogger(cfg.Level, fh, cfg.Formatter)
+	defaultLogger.LogFile = fh
+	return nil
+}
@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultSamplingLRUSize bounds how many distinct keys a SamplingPolicy
+// tracks state for at once, so a flood of unique messages can't grow its
+// memory unbounded.
+const defaultSamplingLRUSize = 4096
+
+// SamplingPolicy decides whether a message identified by key (typically
+// its format string) should be logged or suppressed.
+type SamplingPolicy interface {
+	Allow(key string) bool
+}
+
+var samplingMu sync.RWMutex
+var sampling = map[int32]SamplingPolicy{}
+
+// SetSampling installs policy as the sampling layer for level, consulted
+// by Debug/Info/Warn/Error before they write. Passing a nil policy removes
+// sampling for that level.
+func SetSampling(level int32, policy SamplingPolicy) {
+	samplingMu.Lock()
+	defer samplingMu.Unlock()
+	if policy == nil {
+		delete(sampling, level)
+		return
+	}
+	sampling[level] = policy
+}
+
+func allowSampling(level int32, key string) bool {
+	samplingMu.RLock()
+	policy := sampling[level]
+	samplingMu.RUnlock()
+	if policy == nil {
+		return true
+	}
+	return policy.Allow(key)
+}
+
+// lru is a small fixed-capacity, mutex-protected LRU keyed by string. It
+// backs RateLimiter and BurstSampler so per-message state doesn't grow
+// unbounded under a flood of distinct messages.
+type lru struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value interface{}
+}
+
+func newLRU(capacity int) *lru {
+	return &lru{capacity: capacity, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *lru) getOrCreate(key string, create func() interface{}) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		return el.Value.(*lruEntry).value
+	}
+
+	v := create()
+	el := c.ll.PushFront(&lruEntry{key: key, value: v})
+	c.items[key] = el
+
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*lruEntry).key)
+		}
+	}
+	return v
+}
+
+// RateLimiter allows up to rate messages/sec per key, with burst capacity
+// burst, using a token bucket refilled continuously. Keys are tracked
+// independently (bounded by an LRU) so one noisy message can't starve
+// another's quota.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	cache *lru
+
+	dropped int64
+}
+
+// NewRateLimiter returns a RateLimiter allowing ratePerSec messages/sec per
+// key, with burst allowed immediately.
+func NewRateLimiter(ratePerSec float64, burst int) *RateLimiter {
+	return &RateLimiter{rate: ratePerSec, burst: float64(burst), cache: newLRU(defaultSamplingLRUSize)}
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// Allow reports whether a message with key should be logged, consuming a
+// token from key's bucket if so.
+func (r *RateLimiter) Allow(key string) bool {
+	tb := r.cache.getOrCreate(key, func() interface{} {
+		return &tokenBucket{tokens: r.burst, last: time.Now()}
+	}).(*tokenBucket)
+
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	now := time.Now()
+	tb.tokens += now.Sub(tb.last).Seconds() * r.rate
+	if tb.tokens > r.burst {
+		tb.tokens = r.burst
+	}
+	tb.last = now
+
+	if tb.tokens < 1 {
+		atomic.AddInt64(&r.dropped, 1)
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+// Dropped returns how many messages this limiter has suppressed.
+func (r *RateLimiter) Dropped() int64 {
+	return atomic.LoadInt64(&r.dropped)
+}
+
+// BurstSampler logs the first `first` occurrences of a message within
+// window, then 1 in every `thereafter` occurrences after that, resetting
+// once window has elapsed since the count started.
+type BurstSampler struct {
+	first      int
+	thereafter int
+	window     time.Duration
+	cache      *lru
+
+	dropped int64
+}
+
+// NewBurstSampler returns a BurstSampler with the given first/thereafter/
+// window parameters.
+func NewBurstSampler(first, thereafter int, window time.Duration) *BurstSampler {
+	return &BurstSampler{
+		first:      first,
+		thereafter: thereafter,
+		window:     window,
+		cache:      newLRU(defaultSamplingLRUSize),
+	}
+}
+
+type burstState struct {
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int64
+}
+
+// Allow reports whether a message with key should be logged under the
+// first-N-then-every-Mth policy.
+func (b *BurstSampler) Allow(key string) bool {
+	st := b.cache.getOrCreate(key, func() interface{} {
+		return &burstState{windowStart: time.Now()}
+	}).(*burstState)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(st.windowStart) > b.window {
+		st.windowStart = now
+		st.count = 0
+	}
+	st.count++
+
+	if st.count <= int64(b.first) {
+		return true
+	}
+	if b.thereafter > 0 && (st.count-int64(b.first))%int64(b.thereafter) == 0 {
+		return true
+	}
+	atomic.AddInt64(&b.dropped, 1)
+	return false
+}
+
+// Dropped returns how many messages this sampler has suppressed.
+func (b *BurstSampler) Dropped() int64 {
+	return atomic.LoadInt64(&b.dropped)
+}
@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// tailPollInterval bounds how long Tail waits between checks of a file
+// that has hit EOF without being rotated, so newly appended lines still
+// show up promptly even though we aren't using an OS-level file watch.
+const tailPollInterval = 200 * time.Millisecond
+
+// Tail streams fileName's content as it grows. If fromStart is true, it
+// first replays existing numbered backups oldest-first, then the current
+// file from the beginning; otherwise it starts at the current end of file.
+// Tail follows rotations: doRollover notifies it via h.rotated so it
+// reopens fileName under its new inode instead of reading the renamed
+// backup forever. The returned channel is closed when ctx is canceled or
+// a read error occurs.
+//
+// Tail is best-effort: if rotation happens faster than the consumer can
+// drain out, one or more intermediate backups can be renamed away (and
+// potentially pruned) before Tail gets to them, and those lines are lost.
+func (h *RotatingFileHandler) Tail(ctx context.Context, fromStart bool) (<-chan []byte, error) {
+	h.mu.Lock()
+	fileName := h.fileName
+	backupCount := h.backupCount
+	h.mu.Unlock()
+
+	out := make(chan []byte)
+
+	go func() {
+		defer close(out)
+
+		if fromStart {
+			for i := backupCount; i >= 1; i-- {
+				name := fmt.Sprintf("%s.%d", fileName, i)
+				if !streamFile(ctx, name, out) {
+					return
+				}
+			}
+		}
+
+		f, err := os.Open(fileName)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+
+		if !fromStart {
+			if _, err := f.Seek(0, io.SeekEnd); err != nil {
+				return
+			}
+		}
+
+		r := bufio.NewReader(f)
+		for {
+			line, err := r.ReadBytes('\n')
+			if len(line) > 0 {
+				select {
+				case out <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err == nil {
+				continue
+			}
+			if err != io.EOF {
+				return
+			}
+
+			// fileName may have been rotated away from under f one or
+			// more times since our last check (doRollover's rotated
+			// channel only guarantees a wakeup for the *latest*
+			// rotation, not each one in a burst), so always re-check
+			// file identity rather than trusting the channel alone.
+			if !sameFile(f, fileName) {
+				nf, openErr := os.Open(fileName)
+				if openErr != nil {
+					return
+				}
+				f.Close()
+				f = nf
+				r = bufio.NewReader(f)
+				continue
+			}
+
+			select {
+			case <-h.rotateSignal():
+			case <-ctx.Done():
+				return
+			case <-time.After(tailPollInterval):
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// sameFile reports whether name currently refers to the same file f was
+// opened from, using device+inode identity rather than the path (which may
+// now point at a file created by a later rotation).
+func sameFile(f *os.File, name string) bool {
+	cur, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	other, err := os.Stat(name)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(cur, other)
+}
+
+// streamFile copies name's full content, line by line, to out. It returns
+// false if ctx was canceled mid-stream, true otherwise (including when
+// name doesn't exist, which is expected for backups beyond what's been
+// created yet).
+func streamFile(ctx context.Context, name string, out chan<- []byte) bool {
+	f, err := os.Open(name)
+	if err != nil {
+		return true
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			select {
+			case out <- line:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if err != nil {
+			return true
+		}
+	}
+}